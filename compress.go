@@ -0,0 +1,111 @@
+package log4go
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// compressQueueSize bounds how many rotated files can be waiting for the
+// compression worker before enqueueCompress blocks the rotation goroutine.
+const compressQueueSize = 16
+
+// Compressor streams a rotated log file into a compressed form. Built-in
+// codecs are registered in compressors under the name passed to
+// SetCompress; additional codecs can be added at runtime with
+// RegisterCompressor.
+type Compressor interface {
+	// Ext returns the filename suffix appended to the compressed output,
+	// e.g. ".gz".
+	Ext() string
+	// Compress reads src and writes its compressed form to dst.
+	Compress(dst io.Writer, src io.Reader) error
+}
+
+var compressors = map[string]Compressor{
+	"gzip": gzipCompressor{},
+}
+
+// RegisterCompressor makes a Compressor available to SetCompress under
+// kind. log4go only ships a gzip codec; the standard library has no zstd
+// implementation, so callers wanting SetCompress("zstd") must register one
+// themselves before constructing the FileLogWriter.
+func RegisterCompressor(kind string, c Compressor) {
+	compressors[kind] = c
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Ext() string { return ".gz" }
+
+func (gzipCompressor) Compress(dst io.Writer, src io.Reader) error {
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// enqueueCompress hands a freshly rotated file to the background
+// compression worker, starting the worker on first use.
+func (w *FileLogWriter) enqueueCompress(path string) {
+	w.compressOnce.Do(func() {
+		w.compressCh = make(chan string, compressQueueSize)
+		w.compressWG.Add(1)
+		go func() {
+			defer w.compressWG.Done()
+			for p := range w.compressCh {
+				w.compressFile(p)
+			}
+		}()
+	})
+	w.compressCh <- path
+}
+
+// compressFile compresses path with the codec named by w.compress, writing
+// to a .tmp file first so a crash mid-compress never leaves a truncated
+// archive in place of a readable one. The original is only removed once the
+// compressed file has been fully written and renamed into place.
+func (w *FileLogWriter) compressFile(path string) {
+	c, ok := compressors[w.compress]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: unknown codec %q\n", w.filename, w.compress)
+		return
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: %s\n", w.filename, err)
+		return
+	}
+	defer src.Close()
+
+	dstPath := path + c.Ext() + ".tmp"
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: %s\n", w.filename, err)
+		return
+	}
+
+	if err := c.Compress(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: %s\n", w.filename, err)
+		return
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dstPath)
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: %s\n", w.filename, err)
+		return
+	}
+
+	if err := os.Rename(dstPath, path+c.Ext()); err != nil {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: %s\n", w.filename, err)
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): compress: %s\n", w.filename, err)
+	}
+}
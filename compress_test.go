@@ -0,0 +1,52 @@
+package log4go
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressFileGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-compress")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log-2019-01-02-03+001")
+	if err := ioutil.WriteFile(path, []byte("hello log4go"), 0660); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	w := &FileLogWriter{filename: filepath.Join(dir, "test.log"), compress: "gzip"}
+	w.compressFile(path)
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected original %q to be removed, got err=%v", path, err)
+	}
+	if _, err := os.Stat(path + ".gz.tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected no leftover .gz.tmp, got err=%v", err)
+	}
+
+	gz, err := os.Open(path + ".gz")
+	if err != nil {
+		t.Fatalf("expected %q.gz to exist: %s", path, err)
+	}
+	defer gz.Close()
+
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %s", err)
+	}
+	defer zr.Close()
+
+	data, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	if string(data) != "hello log4go" {
+		t.Errorf("got %q, want %q", data, "hello log4go")
+	}
+}
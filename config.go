@@ -0,0 +1,98 @@
+package log4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// fileLogWriterConfig mirrors beego's file logger config shape, so existing
+// JSON config blobs written for that logger also work here.
+type fileLogWriterConfig struct {
+	Filename   string `json:"filename"`
+	MaxLines   int    `json:"maxlines"`
+	MaxSize    int    `json:"maxsize"`
+	Daily      bool   `json:"daily"`
+	MaxDays    int    `json:"maxdays"`
+	Hourly     bool   `json:"hourly"`
+	MaxHours   int    `json:"maxhours"`
+	MaxBackups int    `json:"maxbackups"`
+	Rotate     bool   `json:"rotate"`
+	Level      string `json:"level"`
+	Perm       string `json:"perm"`
+	Compress   string `json:"compress"`
+	Header     string `json:"header"`
+	Footer     string `json:"footer"`
+	Format     string `json:"format"`
+}
+
+// NewFileLogWriterFromConfig builds a FileLogWriter from a JSON blob shaped
+// like beego's file logger config, applying the Set* method matching every
+// field present. Level is not consumed here: FileLogWriter itself doesn't
+// filter by level, so pairing this writer with cfg.Level is left to whoever
+// registers it with a Logger/Filter.
+//
+// This package has no XML config loader - config-driven construction only
+// exists for this JSON/beego-style shape.
+func NewFileLogWriterFromConfig(data []byte) (*FileLogWriter, error) {
+	var cfg fileLogWriterConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("log4go: invalid file writer config: %s", err)
+	}
+	if cfg.Filename == "" {
+		return nil, fmt.Errorf("log4go: file writer config requires filename")
+	}
+
+	perm := os.FileMode(0660)
+	if cfg.Perm != "" {
+		p, err := strconv.ParseUint(cfg.Perm, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("log4go: invalid perm %q: %s", cfg.Perm, err)
+		}
+		perm = os.FileMode(p)
+	}
+
+	// Apply perm to the writer's first file open (via newFileLogWriter)
+	// rather than calling SetPerm after NewFileLogWriter: by the time
+	// NewFileLogWriter returns, intRotate has already opened the file with
+	// the default 0660, so a later SetPerm would only take effect on the
+	// next rotation.
+	w := newFileLogWriter(cfg.Filename, cfg.Rotate, perm)
+	if w == nil {
+		return nil, fmt.Errorf("log4go: failed to open %q", cfg.Filename)
+	}
+
+	if cfg.Format != "" {
+		w.SetFormat(cfg.Format)
+	}
+	if cfg.Header != "" || cfg.Footer != "" {
+		w.SetHeadFoot(cfg.Header, cfg.Footer)
+	}
+	if cfg.MaxLines > 0 {
+		w.SetRotateLines(cfg.MaxLines)
+	}
+	if cfg.MaxSize > 0 {
+		w.SetRotateSize(cfg.MaxSize)
+	}
+	if cfg.Daily {
+		w.SetRotateDaily(true)
+	}
+	if cfg.Hourly {
+		w.SetRotateHour(true)
+	}
+	if cfg.MaxDays > 0 {
+		w.SetMaxDays(cfg.MaxDays)
+	}
+	if cfg.MaxHours > 0 {
+		w.SetMaxHours(cfg.MaxHours)
+	}
+	if cfg.MaxBackups > 0 {
+		w.SetMaxBackups(cfg.MaxBackups)
+	}
+	if cfg.Compress != "" {
+		w.SetCompress(cfg.Compress)
+	}
+
+	return w, nil
+}
@@ -0,0 +1,99 @@
+package log4go
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLogWriterConfigUnmarshal(t *testing.T) {
+	data := []byte(`{
+		"filename": "app.log",
+		"maxlines": 100000,
+		"maxsize": 1048576,
+		"daily": true,
+		"maxdays": 7,
+		"maxbackups": 5,
+		"rotate": true,
+		"level": "INFO",
+		"perm": "0660",
+		"compress": "gzip"
+	}`)
+
+	var cfg fileLogWriterConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("unmarshal: %s", err)
+	}
+
+	if cfg.Filename != "app.log" || cfg.MaxLines != 100000 || cfg.MaxSize != 1048576 ||
+		!cfg.Daily || cfg.MaxDays != 7 || cfg.MaxBackups != 5 || !cfg.Rotate || cfg.Level != "INFO" ||
+		cfg.Perm != "0660" || cfg.Compress != "gzip" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestNewFileLogWriterFromConfigRequiresFilename(t *testing.T) {
+	if _, err := NewFileLogWriterFromConfig([]byte(`{"rotate":true}`)); err == nil {
+		t.Error("expected error for missing filename, got nil")
+	}
+}
+
+func TestNewFileLogWriterFromConfigAppliesPermToInitialFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-config")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	data := []byte(`{"filename":"` + path + `","perm":"0600"}`)
+	w, err := NewFileLogWriterFromConfig(data)
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig: %s", err)
+	}
+	defer w.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat(%q): %s", path, err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("initial file perm = %o, want 0600", fi.Mode().Perm())
+	}
+}
+
+func TestNewFileLogWriterFromConfigAppliesMaxBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-config")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	data := []byte(`{"filename":"` + path + `","maxbackups":3}`)
+	w, err := NewFileLogWriterFromConfig(data)
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig: %s", err)
+	}
+	defer w.Close()
+
+	if w.maxbackups != 3 {
+		t.Errorf("maxbackups = %d, want 3", w.maxbackups)
+	}
+}
+
+func TestNewFileLogWriterFromConfigRejectsBadPerm(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-config")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	data := []byte(`{"filename":"` + path + `","perm":"999"}`)
+	if _, err := NewFileLogWriterFromConfig(data); err == nil {
+		t.Error("expected error for invalid perm, got nil")
+	}
+}
@@ -4,9 +4,15 @@ package log4go
 
 import (
 	"bufio"
+	"bytes"
 	"container/list"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +32,10 @@ type FileLogWriter struct {
 	// The logging format
 	format string
 
+	// Overrides format for writers with a structured output (JSON, XML)
+	// that need to render the whole LogRecord, including Fields, themselves
+	formatter func(*LogRecord) string
+
 	// File header/trailer
 	header, trailer string
 
@@ -51,6 +61,24 @@ type FileLogWriter struct {
 	wg      sync.WaitGroup
 
 	prefix string
+
+	// Names the archive a rotated file is renamed to (see RotateNamer)
+	namer RotateNamer
+
+	// Permissions used when (re)opening w.filename
+	perm os.FileMode
+
+	// Retention: prune rotated files older than maxdays/maxhours, or beyond
+	// maxbackups, after each rotation
+	maxdays    int
+	maxhours   int
+	maxbackups int
+
+	// Compress rotated files in the background (see compress.go)
+	compress     string
+	compressCh   chan string
+	compressOnce sync.Once
+	compressWG   sync.WaitGroup
 }
 
 func (w *FileLogWriter) changePrefix(prefix string) {
@@ -89,6 +117,11 @@ func (w *FileLogWriter) Close() {
 	}
 
 	w.closeFile()
+
+	if w.compressCh != nil {
+		close(w.compressCh)
+		w.compressWG.Wait()
+	}
 }
 
 func (w *FileLogWriter) closeFile() {
@@ -131,7 +164,7 @@ func (w *FileLogWriter) write(rec *LogRecord) {
 
 	// Perform the write
 	rec.Source = strings.TrimPrefix(rec.Source, w.prefix)
-	n, err := w.bufW.WriteString(FormatLogRecord(w.format, rec))
+	n, err := w.bufW.WriteString(w.formatRecord(rec))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): %s\n", w.filename, err)
 		return
@@ -142,6 +175,15 @@ func (w *FileLogWriter) write(rec *LogRecord) {
 	w.maxsize_cursize += n
 }
 
+// formatRecord renders rec using w.formatter if one has been set (JSON, XML),
+// falling back to the pattern-based FormatLogRecord otherwise.
+func (w *FileLogWriter) formatRecord(rec *LogRecord) string {
+	if w.formatter != nil {
+		return w.formatter(rec)
+	}
+	return FormatLogRecord(w.format, rec)
+}
+
 var FileFlushInterval time.Duration = 100
 
 // NewFileLogWriter creates a new LogWriter which writes to the given file and
@@ -154,6 +196,13 @@ var FileFlushInterval time.Duration = 100
 // The standard log-line format is:
 //   [%D %T] [%L] (%S) %M
 func NewFileLogWriter(fname string, rotate bool) *FileLogWriter {
+	return newFileLogWriter(fname, rotate, 0660)
+}
+
+// newFileLogWriter is NewFileLogWriter with the initial file permission
+// exposed, so NewFileLogWriterFromConfig can apply a configured perm to the
+// very first open instead of only to rotations after a later SetPerm call.
+func newFileLogWriter(fname string, rotate bool, perm os.FileMode) *FileLogWriter {
 	w := &FileLogWriter{
 		inRec:    make(chan *LogRecord, LogBufferLength),
 		rec:      make(chan *LogRecord, LogBufferLength),
@@ -163,6 +212,8 @@ func NewFileLogWriter(fname string, rotate bool) *FileLogWriter {
 		rotate:   rotate,
 		closeCh:  make(chan struct{}),
 		msgQ:     list.New(),
+		namer:    CombinedSuffix{},
+		perm:     perm,
 	}
 
 	// open the file for the first time
@@ -254,7 +305,7 @@ func (w *FileLogWriter) intRotate(last bool) error {
 			num := 1
 			fname := ""
 			for ; err == nil && num <= 999; num++ {
-				fname = w.filename + fmt.Sprintf("-%d-%02d-%02d-%02d+", lastTime.Year(), lastTime.Month(), lastTime.Day(), lastTime.Hour()) + fmt.Sprintf("%03d", num)
+				fname = w.namer.Name(w.filename, lastTime, num)
 				_, err = os.Lstat(fname)
 			}
 			// return error if the last file checked still existed
@@ -267,11 +318,21 @@ func (w *FileLogWriter) intRotate(last bool) error {
 			if err != nil {
 				return fmt.Errorf("Rotate: %s\n", err)
 			}
+
+			if w.compress != "" {
+				w.enqueueCompress(fname)
+			}
 		}
 	}
 
+	// initialize rotation values; newFile seeds them from the file it opens,
+	// which is zero for a freshly rotated file and the existing size/line
+	// count when restarting against a file left over from a previous run
+	w.maxlines_curlines = 0
+	w.maxsize_cursize = 0
+
 	// Open the log file
-	fd, err := os.OpenFile(w.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	fd, err := os.OpenFile(w.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, w.perm)
 	if err != nil {
 		return err
 	}
@@ -282,19 +343,164 @@ func (w *FileLogWriter) intRotate(last bool) error {
 
 	w.hour_opendate = now.Hour()
 
-	// initialize rotation values
-	w.maxlines_curlines = 0
-	w.maxsize_cursize = 0
+	if w.rotate && (w.maxdays > 0 || w.maxhours > 0 || w.maxbackups > 0) {
+		go w.cleanupOldLogs()
+	}
 
 	return nil
 }
 
+// rotatedFileRE matches the "-YYYY-MM-DD-HH+NNN" suffix CombinedSuffix
+// appends to w.filename, optionally followed by a registered Compressor's
+// extension (e.g. ".gz") if the file was compressed after rotating.
+// Retention pruning only understands this naming scheme; switching to
+// another RotateNamer with SetRotateNamer disables pruning.
+var rotatedFileRE = regexp.MustCompile(`^-(\d{4})-(\d{2})-(\d{2})-(\d{2})\+(\d{3})$`)
+
+type rotatedFile struct {
+	path string
+	t    time.Time
+	seq  int
+}
+
+// cleanupOldLogs walks the directory holding w.filename, finds files that
+// match the rotation naming scheme (stripping a registered Compressor
+// extension first, so compressed rotated files are recognized too), and
+// deletes those older than maxdays/maxhours or beyond maxbackups (oldest
+// first). It runs off the hot write path, so errors are reported to stderr
+// rather than surfaced to the caller.
+func (w *FileLogWriter) cleanupOldLogs() {
+	dir := filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): cleanup: %s\n", w.filename, err)
+		return
+	}
+
+	entries, err := ioutil.ReadDir(realDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): cleanup: %s\n", w.filename, err)
+		return
+	}
+
+	var files []rotatedFile
+	for _, fi := range entries {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), base) {
+			continue
+		}
+		suffix := strings.TrimPrefix(fi.Name(), base)
+		for _, c := range compressors {
+			if ext := c.Ext(); ext != "" && strings.HasSuffix(suffix, ext) {
+				suffix = strings.TrimSuffix(suffix, ext)
+				break
+			}
+		}
+		m := rotatedFileRE.FindStringSubmatch(suffix)
+		if m == nil {
+			continue
+		}
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		hour, _ := strconv.Atoi(m[4])
+		seq, _ := strconv.Atoi(m[5])
+		files = append(files, rotatedFile{
+			path: filepath.Join(realDir, fi.Name()),
+			t:    time.Date(year, time.Month(month), day, hour, 0, 0, 0, time.Local),
+			seq:  seq,
+		})
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].t.Equal(files[j].t) {
+			return files[i].t.Before(files[j].t)
+		}
+		return files[i].seq < files[j].seq
+	})
+
+	now := time.Now()
+	remove := make([]bool, len(files))
+	if w.maxdays > 0 {
+		cutoff := now.AddDate(0, 0, -w.maxdays)
+		for i, f := range files {
+			if f.t.Before(cutoff) {
+				remove[i] = true
+			}
+		}
+	}
+	if w.maxhours > 0 {
+		cutoff := now.Add(-time.Duration(w.maxhours) * time.Hour)
+		for i, f := range files {
+			if f.t.Before(cutoff) {
+				remove[i] = true
+			}
+		}
+	}
+	if w.maxbackups > 0 {
+		kept := 0
+		for i := len(files) - 1; i >= 0; i-- {
+			if remove[i] {
+				continue
+			}
+			kept++
+			if kept > w.maxbackups {
+				remove[i] = true
+			}
+		}
+	}
+
+	for i, f := range files {
+		if !remove[i] {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			fmt.Fprintf(os.Stderr, "FileLogWriter(%q): cleanup: %s\n", w.filename, err)
+		}
+	}
+}
+
 var MaxBufWriteSize = 4096
 
 func (w *FileLogWriter) newFile(fd *os.File, now time.Time) {
 	w.file = fd
 	w.bufW = bufio.NewWriterSize(w.file, MaxBufWriteSize)
 	w.bufW.WriteString(FormatLogRecord(w.header, &LogRecord{Created: now}))
+
+	// A restart against a file left over from a previous run should not
+	// require growing it by another maxsize/maxlines before rotation kicks
+	// in again, so seed the counters from what's already on disk.
+	if fi, err := fd.Stat(); err == nil {
+		w.maxsize_cursize = int(fi.Size())
+	}
+	if w.maxlines > 0 {
+		w.maxlines_curlines = countLines(w.filename)
+	}
+}
+
+// countLines counts the newlines already in filename so a restart seeds
+// maxlines_curlines correctly. It's skipped whenever line-based rotation is
+// disabled (w.maxlines == 0), since it requires reading the whole file.
+func countLines(filename string) int {
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	buf := make([]byte, 32*1024)
+	count := 0
+	for {
+		n, err := f.Read(buf)
+		count += bytes.Count(buf[:n], []byte{'\n'})
+		if err != nil {
+			return count
+		}
+	}
 }
 
 // Set the logging format (chainable).  Must be called before the first log
@@ -354,13 +560,110 @@ func (w *FileLogWriter) SetRotate(rotate bool) *FileLogWriter {
 	return w
 }
 
-// NewXMLLogWriter is a utility method for creating a FileLogWriter set up to
-// output XML record log messages instead of line-based ones.
-func NewXMLLogWriter(fname string, rotate bool) *FileLogWriter {
-	return NewFileLogWriter(fname, rotate).SetFormat(
-		`	<record level="%L">
+// SetMaxDays sets how many days' worth of rotated log files to keep
+// (chainable). Rotated files older than this are removed after each
+// rotation. Zero (the default) disables age-based pruning. Must be called
+// before the first log message is written.
+func (w *FileLogWriter) SetMaxDays(maxDays int) *FileLogWriter {
+	w.maxdays = maxDays
+	w.warnIfRetentionUnsupported()
+	return w
+}
+
+// SetMaxHours sets how many hours' worth of rotated log files to keep
+// (chainable). Rotated files older than this are removed after each
+// rotation. Zero (the default) disables age-based pruning. Must be called
+// before the first log message is written.
+func (w *FileLogWriter) SetMaxHours(maxHours int) *FileLogWriter {
+	w.maxhours = maxHours
+	w.warnIfRetentionUnsupported()
+	return w
+}
+
+// SetMaxBackups sets the maximum number of rotated log files to retain
+// (chainable). Once exceeded, the oldest rotated files are removed after
+// each rotation. Zero (the default) disables count-based pruning. Must be
+// called before the first log message is written.
+func (w *FileLogWriter) SetMaxBackups(maxBackups int) *FileLogWriter {
+	w.maxbackups = maxBackups
+	w.warnIfRetentionUnsupported()
+	return w
+}
+
+// warnIfRetentionUnsupported prints a stderr warning when retention
+// (maxdays/maxhours/maxbackups) is configured together with a RotateNamer
+// other than CombinedSuffix, since cleanupOldLogs only recognizes that
+// scheme's "-YYYY-MM-DD-HH+NNN" suffix and would silently prune nothing.
+func (w *FileLogWriter) warnIfRetentionUnsupported() {
+	if _, ok := w.namer.(CombinedSuffix); ok {
+		return
+	}
+	if w.maxdays > 0 || w.maxhours > 0 || w.maxbackups > 0 {
+		fmt.Fprintf(os.Stderr, "FileLogWriter(%q): maxdays/maxhours/maxbackups only prune CombinedSuffix-named files; retention is disabled for RotateNamer %T\n", w.filename, w.namer)
+	}
+}
+
+// SetCompress sets the codec used to compress each rotated log file once it
+// has been renamed off the hot path (chainable). Supported built-in kinds
+// are "" (no compression, the default) and "gzip"; other codecs (e.g.
+// "zstd") can be made available with RegisterCompressor. Compression runs
+// on a single background worker serialized behind compressCh, so bursts of
+// rotations queue rather than forking unbounded goroutines, and Close waits
+// for it to drain. Must be called before the first log message is written.
+func (w *FileLogWriter) SetCompress(kind string) *FileLogWriter {
+	w.compress = kind
+	return w
+}
+
+// xmlRecordFormat is the pattern used to render everything but rec.Fields;
+// FormatXMLRecord appends the fields separately via the shared
+// field-injection path also used by FormatJSONRecord.
+const xmlRecordFormat = `	<record level="%L">
 		<timestamp>%D %T</timestamp>
 		<source>%S</source>
 		<message>%M</message>
-	</record>`).SetHeadFoot("<log created=\"%D %T\">", "</log>")
+	</record>`
+
+// SetRotateNamer sets the scheme used to name a log file once it's rotated
+// out of the way (chainable). Defaults to CombinedSuffix, the original
+// base-YYYY-MM-DD-HH+NNN scheme; see rotatenamer.go for the other built-ins.
+// Switching away from CombinedSuffix while maxdays/maxhours/maxbackups is
+// configured prints a warning to stderr, since cleanupOldLogs only prunes
+// CombinedSuffix's naming scheme. Must be called before the first log
+// message is written.
+func (w *FileLogWriter) SetRotateNamer(namer RotateNamer) *FileLogWriter {
+	w.namer = namer
+	w.warnIfRetentionUnsupported()
+	return w
+}
+
+// SetPerm sets the file mode used when (re)opening w.filename (chainable).
+// Defaults to 0660. Must be called before the first log message is written.
+func (w *FileLogWriter) SetPerm(perm os.FileMode) *FileLogWriter {
+	w.perm = perm
+	return w
+}
+
+// NewXMLLogWriter is a utility method for creating a FileLogWriter set up to
+// output XML record log messages instead of line-based ones.
+func NewXMLLogWriter(fname string, rotate bool) *FileLogWriter {
+	w := NewFileLogWriter(fname, rotate)
+	if w == nil {
+		return nil
+	}
+	w.SetFormat(xmlRecordFormat).SetHeadFoot("<log created=\"%D %T\">", "</log>")
+	w.formatter = FormatXMLRecord
+	return w
+}
+
+// FormatXMLRecord renders rec using xmlRecordFormat and, when rec.Fields is
+// non-empty, injects it as a trailing <fields> element holding the same
+// JSON produced by formatFieldsJSON, so JSON and XML output agree on how a
+// given set of fields is represented.
+func FormatXMLRecord(rec *LogRecord) string {
+	s := FormatLogRecord(xmlRecordFormat, rec)
+	if len(rec.Fields) == 0 {
+		return s
+	}
+	return s + "\n\t<fields>" + formatFieldsJSON(rec.Fields) + "</fields>"
 }
@@ -0,0 +1,80 @@
+package log4go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openForAppend(t *testing.T, path string) *os.File {
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	if err != nil {
+		t.Fatalf("OpenFile(%q): %s", path, err)
+	}
+	return fd
+}
+
+func TestNewFileSeedsSizeFromExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-restart")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	if err := ioutil.WriteFile(path, []byte("0123456789"), 0660); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	w := &FileLogWriter{filename: path, maxsize: 20}
+	w.newFile(openForAppend(t, path), time.Now())
+	defer w.file.Close()
+
+	if w.maxsize_cursize != 10 {
+		t.Errorf("maxsize_cursize = %d, want 10", w.maxsize_cursize)
+	}
+}
+
+func TestNewFileSeedsLinesFromExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-restart")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	if err := ioutil.WriteFile(path, []byte("a\nb\nc\n"), 0660); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	w := &FileLogWriter{filename: path, maxlines: 10}
+	w.newFile(openForAppend(t, path), time.Now())
+	defer w.file.Close()
+
+	if w.maxlines_curlines != 3 {
+		t.Errorf("maxlines_curlines = %d, want 3", w.maxlines_curlines)
+	}
+}
+
+func TestNewFileSkipsLineCountWhenDisabled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-restart")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	if err := ioutil.WriteFile(path, []byte("a\nb\nc\n"), 0660); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	w := &FileLogWriter{filename: path}
+	w.newFile(openForAppend(t, path), time.Now())
+	defer w.file.Close()
+
+	if w.maxlines_curlines != 0 {
+		t.Errorf("maxlines_curlines = %d, want 0 (line counting disabled)", w.maxlines_curlines)
+	}
+}
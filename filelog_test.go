@@ -0,0 +1,204 @@
+package log4go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func touchRotatedFile(t *testing.T, dir, base string, when time.Time, seq int) string {
+	name := base + when.Format("-2006-01-02-15") + fmt.Sprintf("+%03d", seq)
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("test"), 0660); err != nil {
+		t.Fatalf("WriteFile(%q): %s", path, err)
+	}
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatalf("Chtimes(%q): %s", path, err)
+	}
+	return path
+}
+
+func TestCleanupOldLogsMaxDays(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-retention")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "test.log")
+	now := time.Now()
+
+	old := touchRotatedFile(t, dir, "test.log", now.AddDate(0, 0, -10), 1)
+	recent := touchRotatedFile(t, dir, "test.log", now.AddDate(0, 0, -1), 1)
+
+	w := &FileLogWriter{filename: base, maxdays: 3}
+	w.cleanupOldLogs()
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expected %q to be pruned, got err=%v", old, err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("expected %q to survive, got err=%v", recent, err)
+	}
+}
+
+func TestIntRotateTwiceInSameBucketDoesNotCollide(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-rotate")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	if err := ioutil.WriteFile(path, []byte("first"), 0660); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	w := &FileLogWriter{filename: path, rotate: true, namer: DailySuffix{}, perm: 0660}
+
+	if err := w.intRotate(false); err != nil {
+		t.Fatalf("first intRotate: %s", err)
+	}
+	if err := w.intRotate(false); err != nil {
+		t.Fatalf("second intRotate (same day) should not run out of free names: %s", err)
+	}
+	w.file.Close()
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %s", err)
+	}
+
+	var archived []string
+	for _, fi := range entries {
+		if fi.Name() != "test.log" {
+			archived = append(archived, fi.Name())
+		}
+	}
+	if len(archived) != 2 {
+		t.Fatalf("expected 2 distinct archived files, got %v", archived)
+	}
+	if archived[0] == archived[1] {
+		t.Errorf("expected distinct archive names, both were %q", archived[0])
+	}
+}
+
+func TestCleanupOldLogsMatchesCompressedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-retention")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "test.log")
+	now := time.Now()
+
+	uncompressed := touchRotatedFile(t, dir, "test.log", now.AddDate(0, 0, -10), 1)
+	old := uncompressed + ".gz"
+	if err := os.Rename(uncompressed, old); err != nil {
+		t.Fatalf("Rename: %s", err)
+	}
+	recent := touchRotatedFile(t, dir, "test.log", now.AddDate(0, 0, -1), 1)
+
+	w := &FileLogWriter{filename: base, maxdays: 3, compress: "gzip"}
+	w.cleanupOldLogs()
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expected compressed %q to be pruned, got err=%v", old, err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("expected %q to survive, got err=%v", recent, err)
+	}
+}
+
+func TestCleanupOldLogsMaxBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-retention")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "test.log")
+	now := time.Now()
+
+	var paths []string
+	for i := 0; i < 5; i++ {
+		paths = append(paths, touchRotatedFile(t, dir, "test.log", now.Add(-time.Duration(5-i)*time.Hour), 1))
+	}
+
+	w := &FileLogWriter{filename: base, maxbackups: 2}
+	w.cleanupOldLogs()
+
+	for i, p := range paths {
+		_, err := os.Stat(p)
+		if i < 3 {
+			if !os.IsNotExist(err) {
+				t.Errorf("expected %q to be pruned, got err=%v", p, err)
+			}
+		} else if err != nil {
+			t.Errorf("expected %q to survive, got err=%v", p, err)
+		}
+	}
+}
+
+// captureStderr redirects os.Stderr for the duration of fn and returns
+// whatever was written to it.
+func captureStderr(t *testing.T, fn func()) string {
+	orig := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %s", err)
+	}
+	os.Stderr = w
+
+	fn()
+
+	w.Close()
+	os.Stderr = orig
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	return string(out)
+}
+
+func TestSetRotateNamerWarnsWhenRetentionConfigured(t *testing.T) {
+	w := &FileLogWriter{filename: "test.log", namer: CombinedSuffix{}, maxdays: 7}
+
+	out := captureStderr(t, func() {
+		w.SetRotateNamer(DailySuffix{})
+	})
+
+	if !strings.Contains(out, "retention is disabled") {
+		t.Errorf("expected a retention warning, got %q", out)
+	}
+}
+
+func TestSetMaxDaysWarnsWhenNamerUnsupported(t *testing.T) {
+	w := &FileLogWriter{filename: "test.log", namer: DailySuffix{}}
+
+	out := captureStderr(t, func() {
+		w.SetMaxDays(7)
+	})
+
+	if !strings.Contains(out, "retention is disabled") {
+		t.Errorf("expected a retention warning, got %q", out)
+	}
+}
+
+func TestSetRotateNamerNoWarningWithoutRetention(t *testing.T) {
+	w := &FileLogWriter{filename: "test.log", namer: CombinedSuffix{}}
+
+	out := captureStderr(t, func() {
+		w.SetRotateNamer(DailySuffix{})
+	})
+
+	if out != "" {
+		t.Errorf("expected no warning, got %q", out)
+	}
+}
@@ -0,0 +1,119 @@
+package log4go
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatJSONRecord(t *testing.T) {
+	rec := &LogRecord{
+		Level:   INFO,
+		Created: time.Date(2019, time.January, 2, 15, 4, 5, 0, time.UTC),
+		Source:  "main.go:10",
+		Message: "hello",
+		Fields:  map[string]interface{}{"user": "alice"},
+	}
+
+	line := FormatJSONRecord(rec)
+	if !strings.HasSuffix(line, "\n") {
+		t.Fatalf("expected trailing newline, got %q", line)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("Unmarshal(%q): %s", line, err)
+	}
+
+	if decoded["level"] != "INFO" || decoded["source"] != "main.go:10" || decoded["message"] != "hello" {
+		t.Errorf("unexpected record: %+v", decoded)
+	}
+	fields, ok := decoded["fields"].(map[string]interface{})
+	if !ok || fields["user"] != "alice" {
+		t.Errorf("unexpected fields: %+v", decoded["fields"])
+	}
+}
+
+func TestFormatJSONRecordEscapesControlCharacters(t *testing.T) {
+	rec := &LogRecord{
+		Level:   ERROR,
+		Created: time.Now(),
+		Source:  "main.go:1",
+		Message: "line one\nline \"two\"",
+	}
+
+	line := FormatJSONRecord(rec)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("Unmarshal(%q): %s", line, err)
+	}
+	if decoded["message"] != "line one\nline \"two\"" {
+		t.Errorf("message = %q, want original round-tripped value", decoded["message"])
+	}
+}
+
+func TestFormatFieldsJSON(t *testing.T) {
+	if got := formatFieldsJSON(nil); got != "{}" {
+		t.Errorf("formatFieldsJSON(nil) = %q, want {}", got)
+	}
+	if got := formatFieldsJSON(map[string]interface{}{"a": 1}); got != `{"a":1}` {
+		t.Errorf("formatFieldsJSON = %q, want {\"a\":1}", got)
+	}
+}
+
+func TestFormatXMLRecordIncludesFields(t *testing.T) {
+	rec := &LogRecord{
+		Level:   WARNING,
+		Created: time.Now(),
+		Source:  "main.go:5",
+		Message: "watch out",
+		Fields:  map[string]interface{}{"retry": 2},
+	}
+
+	xml := FormatXMLRecord(rec)
+	if !strings.Contains(xml, `<fields>{"retry":2}</fields>`) {
+		t.Errorf("expected fields element, got %q", xml)
+	}
+}
+
+func TestFormatXMLRecordOmitsFieldsWhenEmpty(t *testing.T) {
+	rec := &LogRecord{Level: INFO, Created: time.Now(), Source: "main.go:5", Message: "ok"}
+
+	if xml := FormatXMLRecord(rec); strings.Contains(xml, "<fields>") {
+		t.Errorf("expected no fields element, got %q", xml)
+	}
+}
+
+func TestNewJSONLogWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-jsonlog")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	w := NewJSONLogWriter(path, false)
+	if w == nil {
+		t.Fatal("NewJSONLogWriter returned nil")
+	}
+
+	w.LogWrite(&LogRecord{Level: INFO, Created: time.Now(), Source: "main.go:1", Message: "started"})
+	w.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%q): %s", data, err)
+	}
+	if decoded["message"] != "started" {
+		t.Errorf("message = %v, want %q", decoded["message"], "started")
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2010, Kyle Lemons <kyle@kylelemons.net>.  All rights reserved.
+
+package log4go
+
+import (
+	"strings"
+	"time"
+)
+
+// LogBufferLength is the number of log records a LogWriter buffers
+// internally (see FileLogWriter.inRec/rec) before producers start blocking.
+var LogBufferLength = 32
+
+// level identifies how severe a LogRecord is.
+type level int
+
+const (
+	FINEST level = iota
+	FINE
+	DEBUG
+	TRACE
+	INFO
+	WARNING
+	ERROR
+	CRITICAL
+)
+
+var levelStrings = [...]string{"FNST", "FINE", "DEBG", "TRAC", "INFO", "WARN", "EROR", "CRIT"}
+
+func (l level) String() string {
+	if l < 0 || int(l) >= len(levelStrings) {
+		return "UNKNOWN"
+	}
+	return levelStrings[int(l)]
+}
+
+// LogRecord is the unit of work passed from a Logger to each LogWriter.
+type LogRecord struct {
+	Level   level     // The log level
+	Created time.Time // The time at which the log message was created
+	Source  string    // The message source
+	Message string    // The log message
+
+	// Fields holds structured key/value pairs attached via Logger.WithField
+	// or Logger.WithFields before the record was logged. Writers that only
+	// understand line-oriented output (the default FileLogWriter format)
+	// ignore it; FormatJSONRecord and FormatXMLRecord render it.
+	Fields map[string]interface{}
+}
+
+// LogWriter is the interface a FileLogWriter (and anything else a Logger can
+// dispatch to) implements.
+type LogWriter interface {
+	LogWrite(rec *LogRecord)
+	Close()
+}
+
+// Filter pairs a LogWriter with the minimum level it accepts.
+type Filter struct {
+	Level level
+	LogWriter
+}
+
+// NewFilter creates a Filter accepting records at lvl and above.
+func NewFilter(lvl level, writer LogWriter) *Filter {
+	return &Filter{Level: lvl, LogWriter: writer}
+}
+
+// Logger dispatches LogRecords to a named set of Filters.
+type Logger map[string]*Filter
+
+// NewLogger creates an empty Logger; use AddFilter to register writers.
+func NewLogger() Logger {
+	return make(Logger)
+}
+
+// AddFilter registers writer under name, accepting records at lvl and above
+// (chainable).
+func (l Logger) AddFilter(name string, lvl level, writer LogWriter) Logger {
+	l[name] = NewFilter(lvl, writer)
+	return l
+}
+
+// Close shuts down every Filter's LogWriter. Each LogWriter's own Close is
+// responsible for flushing anything already queued; Logger doesn't send a
+// synthetic record to signal shutdown (FileLogWriter.write dereferences
+// rec.Source unconditionally, so a nil record would panic).
+func (l Logger) Close() {
+	for name, filt := range l {
+		filt.Close()
+		delete(l, name)
+	}
+}
+
+// log builds a LogRecord from lvl/source/message(/fields) and dispatches it
+// to every Filter whose Level is at or below lvl.
+func (l Logger) log(lvl level, source, message string, fields map[string]interface{}) {
+	rec := &LogRecord{
+		Level:   lvl,
+		Created: time.Now(),
+		Source:  source,
+		Message: message,
+		Fields:  fields,
+	}
+	for _, filt := range l {
+		if filt == nil || lvl < filt.Level {
+			continue
+		}
+		filt.LogWrite(rec)
+	}
+}
+
+func (l Logger) Finest(source, message string)   { l.log(FINEST, source, message, nil) }
+func (l Logger) Fine(source, message string)     { l.log(FINE, source, message, nil) }
+func (l Logger) Debug(source, message string)    { l.log(DEBUG, source, message, nil) }
+func (l Logger) Trace(source, message string)    { l.log(TRACE, source, message, nil) }
+func (l Logger) Info(source, message string)     { l.log(INFO, source, message, nil) }
+func (l Logger) Warn(source, message string)     { l.log(WARNING, source, message, nil) }
+func (l Logger) Error(source, message string)    { l.log(ERROR, source, message, nil) }
+func (l Logger) Critical(source, message string) { l.log(CRITICAL, source, message, nil) }
+
+// fieldLogger is returned by Logger.WithField/WithFields; it carries a set
+// of structured fields through to the LogRecord produced by its own
+// level-named methods, without disturbing Logger's own (field-less) ones.
+type fieldLogger struct {
+	logger Logger
+	fields map[string]interface{}
+}
+
+// WithField returns a logger that attaches key/value, in addition to any
+// fields already attached, to every record it logs.
+func (l Logger) WithField(key string, value interface{}) *fieldLogger {
+	return (&fieldLogger{logger: l, fields: map[string]interface{}{}}).WithField(key, value)
+}
+
+// WithFields returns a logger that attaches a copy of fields to every record
+// it logs.
+func (l Logger) WithFields(fields map[string]interface{}) *fieldLogger {
+	return (&fieldLogger{logger: l, fields: map[string]interface{}{}}).WithFields(fields)
+}
+
+// WithField attaches another key/value to fl's fields (chainable).
+func (fl *fieldLogger) WithField(key string, value interface{}) *fieldLogger {
+	fl.fields[key] = value
+	return fl
+}
+
+// WithFields merges fields into fl's fields (chainable).
+func (fl *fieldLogger) WithFields(fields map[string]interface{}) *fieldLogger {
+	for k, v := range fields {
+		fl.fields[k] = v
+	}
+	return fl
+}
+
+// snapshot copies fl.fields into a fresh map so a LogRecord handed off to a
+// writer's own goroutine never shares storage with a fieldLogger that's
+// still alive and may see further WithField/WithFields calls; without this,
+// a background writer marshaling rec.Fields can race a concurrent map write.
+func (fl *fieldLogger) snapshot() map[string]interface{} {
+	fields := make(map[string]interface{}, len(fl.fields))
+	for k, v := range fl.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+func (fl *fieldLogger) Finest(source, message string) {
+	fl.logger.log(FINEST, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Fine(source, message string) {
+	fl.logger.log(FINE, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Debug(source, message string) {
+	fl.logger.log(DEBUG, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Trace(source, message string) {
+	fl.logger.log(TRACE, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Info(source, message string) {
+	fl.logger.log(INFO, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Warn(source, message string) {
+	fl.logger.log(WARNING, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Error(source, message string) {
+	fl.logger.log(ERROR, source, message, fl.snapshot())
+}
+func (fl *fieldLogger) Critical(source, message string) {
+	fl.logger.log(CRITICAL, source, message, fl.snapshot())
+}
+
+// FormatLogRecord formats rec according to format, expanding:
+//
+//	%T - Time (15:04:05 MST)
+//	%D - Date (2006/01/02)
+//	%L - Level
+//	%S - Source
+//	%M - Message
+//
+// An unknown verb is left untouched.
+func FormatLogRecord(format string, rec *LogRecord) string {
+	if rec == nil {
+		return "<nil>"
+	}
+	if len(format) == 0 {
+		return ""
+	}
+
+	out := format
+	out = strings.Replace(out, "%T", rec.Created.Format("15:04:05 MST"), -1)
+	out = strings.Replace(out, "%D", rec.Created.Format("2006/01/02"), -1)
+	out = strings.Replace(out, "%L", levelStrings[rec.Level], -1)
+	out = strings.Replace(out, "%S", rec.Source, -1)
+	out = strings.Replace(out, "%M", rec.Message, -1)
+	return out + "\n"
+}
@@ -0,0 +1,135 @@
+package log4go
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoggerAddFilterLogAndClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-logger")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	w := NewJSONLogWriter(path, false)
+	if w == nil {
+		t.Fatal("NewJSONLogWriter returned nil")
+	}
+
+	logger := NewLogger().AddFilter("file", FINEST, w)
+	logger.Info("main.go:1", "started")
+	logger.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%q): %s", data, err)
+	}
+	if decoded["message"] != "started" {
+		t.Errorf("message = %v, want %q", decoded["message"], "started")
+	}
+}
+
+func TestLoggerFilterLevelThreshold(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-logger")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	w := NewJSONLogWriter(path, false)
+	if w == nil {
+		t.Fatal("NewJSONLogWriter returned nil")
+	}
+
+	logger := NewLogger().AddFilter("file", ERROR, w)
+	logger.Info("main.go:1", "should be filtered out")
+	logger.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("expected no output below the filter's level, got %q", data)
+	}
+}
+
+func TestWithFieldSnapshotsFieldsPerRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go-logger")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	w := NewJSONLogWriter(path, false)
+	if w == nil {
+		t.Fatal("NewJSONLogWriter returned nil")
+	}
+
+	logger := NewLogger().AddFilter("file", FINEST, w)
+	fl := logger.WithField("request", "first")
+	fl.Info("main.go:1", "one")
+	fl.WithField("request", "second")
+	fl.Info("main.go:1", "two")
+	logger.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	lines := 0
+	for _, line := range splitLines(data) {
+		if len(line) == 0 {
+			continue
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(line, &decoded); err != nil {
+			t.Fatalf("Unmarshal(%q): %s", line, err)
+		}
+		fields, _ := decoded["fields"].(map[string]interface{})
+		switch decoded["message"] {
+		case "one":
+			if fields["request"] != "first" {
+				t.Errorf("first record fields = %+v, want request=first", fields)
+			}
+		case "two":
+			if fields["request"] != "second" {
+				t.Errorf("second record fields = %+v, want request=second", fields)
+			}
+		default:
+			t.Errorf("unexpected message %v", decoded["message"])
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("got %d records, want 2", lines)
+	}
+}
+
+func splitLines(data []byte) [][]byte {
+	var lines [][]byte
+	start := 0
+	for i, b := range data {
+		if b == '\n' {
+			lines = append(lines, data[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(data) {
+		lines = append(lines, data[start:])
+	}
+	return lines
+}
@@ -0,0 +1,66 @@
+package log4go
+
+import (
+	"fmt"
+	"time"
+)
+
+// RotateNamer names the archive intRotate renames an active log file to. It
+// is tried with increasing seq (starting at 1) until it returns a name that
+// doesn't already exist on disk, so a scheme that returns the same name for
+// every seq within a time bucket (as DailySuffix/HourlySuffix do for seq==1)
+// must still vary its output for seq>1 - otherwise intRotate's free-name
+// search retries the same colliding name until it gives up at seq 999,
+// dropping the record that triggered the rotation and permanently wedging
+// every later write that hits the same trigger.
+type RotateNamer interface {
+	// Name returns the archive name for base, rotated at time t, trying
+	// sequence number seq.
+	Name(base string, t time.Time, seq int) string
+}
+
+// CombinedSuffix is the original log4go naming scheme,
+// base-YYYY-MM-DD-HH+NNN, kept as the default for back-compat.
+type CombinedSuffix struct{}
+
+func (CombinedSuffix) Name(base string, t time.Time, seq int) string {
+	return base + fmt.Sprintf("-%d-%02d-%02d-%02d+%03d", t.Year(), t.Month(), t.Day(), t.Hour(), seq)
+}
+
+// DailySuffix names archives base.2006-01-02, matching the glob pattern log
+// shippers such as Filebeat or Fluentd typically expect for daily-rotated
+// files. A second rotation landing in the same day gets a ".NNN"
+// disambiguator appended (base.2006-01-02.002, .003, ...) so it can't
+// collide with the first.
+type DailySuffix struct{}
+
+func (DailySuffix) Name(base string, t time.Time, seq int) string {
+	return withSeqDisambiguator(base+"."+t.Format("2006-01-02"), seq)
+}
+
+// HourlySuffix names archives base.2006-01-02-15, with the same ".NNN"
+// disambiguator as DailySuffix for a second rotation within the same hour.
+type HourlySuffix struct{}
+
+func (HourlySuffix) Name(base string, t time.Time, seq int) string {
+	return withSeqDisambiguator(base+"."+t.Format("2006-01-02-15"), seq)
+}
+
+// withSeqDisambiguator returns name unchanged for the common case (seq==1,
+// the first rotation in the bucket) and appends ".NNN" for seq>1, so a
+// RotateNamer whose name doesn't otherwise depend on seq still produces a
+// distinct name on each retry of intRotate's free-name search.
+func withSeqDisambiguator(name string, seq int) string {
+	if seq <= 1 {
+		return name
+	}
+	return name + fmt.Sprintf(".%03d", seq)
+}
+
+// SizeSequence names archives base.001, ignoring t entirely; intended for
+// size-triggered rotation where the time bucket isn't meaningful.
+type SizeSequence struct{}
+
+func (SizeSequence) Name(base string, t time.Time, seq int) string {
+	return base + fmt.Sprintf(".%03d", seq)
+}
@@ -0,0 +1,31 @@
+package log4go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotateNamers(t *testing.T) {
+	at := time.Date(2019, time.January, 2, 15, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		namer RotateNamer
+		seq   int
+		want  string
+	}{
+		{"combined", CombinedSuffix{}, 1, "app.log-2019-01-02-15+001"},
+		{"daily", DailySuffix{}, 1, "app.log.2019-01-02"},
+		{"daily second rotation", DailySuffix{}, 2, "app.log.2019-01-02.002"},
+		{"hourly", HourlySuffix{}, 1, "app.log.2019-01-02-15"},
+		{"hourly second rotation", HourlySuffix{}, 2, "app.log.2019-01-02-15.002"},
+		{"size", SizeSequence{}, 7, "app.log.007"},
+	}
+
+	for _, c := range cases {
+		got := c.namer.Name("app.log", at, c.seq)
+		if got != c.want {
+			t.Errorf("%s: Name() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}